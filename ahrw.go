@@ -4,9 +4,12 @@ package ahrw
 
 import (
 	"bytes"
+	"container/list"
 	"encoding/binary"
 	"errors"
+	"math"
 	"slices"
+	"sync"
 	"sync/atomic"
 
 	"github.com/zeebo/xxh3"
@@ -50,6 +53,17 @@ type Node interface {
 
 var _ Node = &Server{}
 
+// WeightedNode is the interface for load balancing targets that carry a
+// capacity share, used for weighted rendezvous hashing.
+type WeightedNode interface {
+	Node
+	// Weight returns this node's capacity share. Only the value of a
+	// node's weight relative to other nodes' matters; weights don't
+	// need to sum to anything in particular and don't need to be
+	// integers.
+	Weight() float64
+}
+
 type noCopy struct{}
 
 func (*noCopy) Lock()   {}
@@ -67,14 +81,56 @@ func (*noCopy) Unlock() {}
 // for weighted case) and/or high request rates.
 //
 // AHRW is safe for concurrent use by multiple goroutines and for
-// efficiency should only be created once and re-used. On the other hand
-// AHRW instance should be recreated to change set of active nodes.
+// efficiency should only be created once and re-used. To change the set
+// of active nodes, use [AHRW.WithNode] / [AHRW.WithoutNode], or
+// recreate the instance from scratch with the appropriate constructor.
+
+// ahrwKind records which constructor built an AHRW instance, so
+// [AHRW.WithNode] and [AHRW.WithoutNode] know how to rebuild one of the
+// specialized variants when they can't reuse its cached slot mappings
+// directly.
+type ahrwKind int
+
+const (
+	kindPlain ahrwKind = iota
+	kindTopK
+	kindBounded
+	kindCache
+)
+
 type AHRW struct {
-	_     noCopy
-	nodes []Node
-	m     []atomic.Pointer[Node]
+	_       noCopy
+	nodes   []Node
+	nslots  uint64
+	m       []atomic.Pointer[Node]
+	topk    []atomic.Pointer[[]Node]
+	k       int
+	weights []float64
+	loads   []int
+
+	rankOnce sync.Once
+	rank     []atomic.Pointer[[]uint32]
+
+	cache *slotCache
+
+	kind       ahrwKind
+	loadFactor float64
+	cacheSize  int
 }
 
+// ahrwKind records which constructor built an AHRW instance, so
+// [AHRW.WithNode] and [AHRW.WithoutNode] know how to rebuild one of the
+// specialized variants when they can't reuse its cached slot mappings
+// directly.
+type ahrwKind int
+
+const (
+	kindPlain ahrwKind = iota
+	kindTopK
+	kindBounded
+	kindCache
+)
+
 func uniqNodes(nodes []Node) []Node {
 	sortedNodes := make([]Node, len(nodes))
 	copy(sortedNodes, nodes)
@@ -86,6 +142,28 @@ func uniqNodes(nodes []Node) []Node {
 	})
 }
 
+// uniqWeightedNodes sorts and deduplicates nodes by NodeID, same as
+// uniqNodes, and splits the result into parallel Node and weight slices
+// suitable for storing in AHRW.
+func uniqWeightedNodes(nodes []WeightedNode) ([]Node, []float64) {
+	sortedNodes := make([]WeightedNode, len(nodes))
+	copy(sortedNodes, nodes)
+	slices.SortStableFunc(sortedNodes, func(a, b WeightedNode) int {
+		return bytes.Compare(a.NodeID(), b.NodeID())
+	})
+	sortedNodes = slices.CompactFunc(sortedNodes, func(a, b WeightedNode) bool {
+		return bytes.Equal(a.NodeID(), b.NodeID())
+	})
+
+	plain := make([]Node, len(sortedNodes))
+	weights := make([]float64, len(sortedNodes))
+	for i, n := range sortedNodes {
+		plain[i] = n
+		weights[i] = n.Weight()
+	}
+	return plain, weights
+}
+
 var (
 	// ErrZeroSlots indicates incorrect invocation of New with zero slots.
 	ErrZeroSlots = errors.New("number of slots can't be zero")
@@ -95,8 +173,102 @@ var (
 	// ErrSlotOutOfRange is returned when requested slot is
 	// beyond index range of created AHRW instance.
 	ErrSlotOutOfRange = errors.New("slot out of range")
+	// ErrInvalidK is returned when requested number of top nodes is
+	// not a positive number.
+	ErrInvalidK = errors.New("k must be positive")
+	// ErrInvalidLoadFactor indicates incorrect invocation of NewBounded
+	// with a load factor below 1.0.
+	ErrInvalidLoadFactor = errors.New("load factor must be >= 1.0")
+	// ErrInvalidCacheSize indicates incorrect invocation of
+	// NewWithCache with a non-positive cache size.
+	ErrInvalidCacheSize = errors.New("cache size must be positive")
 )
 
+// slotCacheShards is the number of shards the slot cache maintained by
+// NewWithCache is split into, each with its own mutex, to keep lock
+// contention down under concurrent lookups.
+const slotCacheShards = 32
+
+// slotCacheEntry is the LRU payload for a single cached slot.
+type slotCacheEntry struct {
+	slot uint64
+	node *Node
+}
+
+// lruShard is one shard of a slotCache: a capacity-bounded LRU with its
+// own mutex, so concurrent lookups landing in different shards don't
+// contend with each other.
+type lruShard struct {
+	mu    sync.Mutex
+	cap   int
+	order list.List
+	items map[uint64]*list.Element
+}
+
+func (s *lruShard) get(slot uint64) (*Node, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[slot]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(el)
+	return el.Value.(*slotCacheEntry).node, true
+}
+
+func (s *lruShard) add(slot uint64, node *Node) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[slot]; ok {
+		el.Value.(*slotCacheEntry).node = node
+		s.order.MoveToFront(el)
+		return
+	}
+	el := s.order.PushFront(&slotCacheEntry{slot: slot, node: node})
+	s.items[slot] = el
+	if s.order.Len() > s.cap {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*slotCacheEntry).slot)
+	}
+}
+
+// slotCache is a fixed-size, sharded LRU cache mapping slots to nodes.
+// It's used in place of the dense per-slot atomic.Pointer array by AHRW
+// instances created with NewWithCache, trading a bounded amount of
+// memory for the ability to use a much larger nslots than the working
+// set of hot keys actually needs.
+type slotCache struct {
+	shards [slotCacheShards]lruShard
+}
+
+func newSlotCache(size int) *slotCache {
+	c := &slotCache{}
+	shardCap := (size + slotCacheShards - 1) / slotCacheShards
+	if shardCap < 1 {
+		shardCap = 1
+	}
+	for i := range c.shards {
+		c.shards[i].cap = shardCap
+		c.shards[i].items = make(map[uint64]*list.Element, shardCap)
+	}
+	return c
+}
+
+func (c *slotCache) shardFor(slot uint64) *lruShard {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, slot)
+	return &c.shards[xxh3.Hash(buf)%slotCacheShards]
+}
+
+func (c *slotCache) get(slot uint64) (*Node, bool) {
+	return c.shardFor(slot).get(slot)
+}
+
+func (c *slotCache) add(slot uint64, node *Node) {
+	c.shardFor(slot).add(slot, node)
+}
+
 // New returns instance of AHRW with nslots slots distributed to nodes.
 //
 // Reasonable choice of nslots is two orders of magnitude higher than
@@ -116,26 +288,285 @@ func New(nslots uint64, nodes []Node) (*AHRW, error) {
 	}
 
 	return &AHRW{
-		nodes: uniqNodes(nodes),
-		m:     make([]atomic.Pointer[Node], nslots),
+		nodes:  uniqNodes(nodes),
+		nslots: nslots,
+		m:      make([]atomic.Pointer[Node], nslots),
+	}, nil
+}
+
+// NewTopK returns an instance of AHRW which, in addition to regular
+// single-node lookup, memoizes the top-k ranked nodes per slot so that
+// [AHRW.NodesForSlot] and [AHRW.NodesForBytes] remain amortized O(1) for
+// any requested number of replicas up to k.
+//
+// k must be between 1 and len(nodes); it is clamped to len(nodes) if
+// greater. Memory cost scales with k: each slot stores k node pointers
+// instead of one, so pick k no larger than the replication factor
+// actually needed.
+func NewTopK(nslots uint64, k int, nodes []Node) (*AHRW, error) {
+	if nslots == 0 {
+		return nil, ErrZeroSlots
+	}
+	if len(nodes) == 0 {
+		return nil, ErrZeroNodes
+	}
+	if k <= 0 {
+		return nil, ErrInvalidK
+	}
+
+	uniq := uniqNodes(nodes)
+	if k > len(uniq) {
+		k = len(uniq)
+	}
+
+	return &AHRW{
+		nodes:  uniq,
+		nslots: nslots,
+		topk:   make([]atomic.Pointer[[]Node], nslots),
+		k:      k,
+		kind:   kindTopK,
 	}, nil
 }
 
+// NewWeighted returns an instance of AHRW with nslots slots distributed to
+// nodes proportionally to their [WeightedNode.Weight].
+//
+// Internally this uses the logarithmic method for weighted rendezvous
+// hashing: for node i with weight w and a hash-derived value p uniform
+// in (0,1), the score is -w/ln(p), and the node with the highest score
+// wins the slot. This preserves the minimal-disruption property of
+// plain rendezvous hashing: adding a node with weight w to a cluster of
+// total weight W only remaps ~w/(W+w) of the keyspace.
+func NewWeighted(nslots uint64, nodes []WeightedNode) (*AHRW, error) {
+	if nslots == 0 {
+		return nil, ErrZeroSlots
+	}
+	if len(nodes) == 0 {
+		return nil, ErrZeroNodes
+	}
+
+	plain, weights := uniqWeightedNodes(nodes)
+
+	return &AHRW{
+		nodes:   plain,
+		nslots:  nslots,
+		m:       make([]atomic.Pointer[Node], nslots),
+		weights: weights,
+	}, nil
+}
+
+// NewWithCache returns an instance of AHRW with nslots slots distributed
+// to nodes, as with New, except that computed slot->node mappings are
+// kept in a fixed-size, sharded LRU cache instead of a dense nslots-long
+// table. The cache is split into 32 shards of ceil(cacheSize/32) entries
+// each, so it holds at most cacheSize entries rounded up to a multiple
+// of 32. This makes it practical to pick an nslots orders of magnitude
+// larger than the working set of hot keys (for finer-grained, less
+// disruptive rebalancing) without resident memory scaling with nslots:
+// memory is instead bounded by that cache capacity, and slots evicted
+// from the cache are simply recomputed on next access.
+func NewWithCache(nslots uint64, cacheSize int, nodes []Node) (*AHRW, error) {
+	if nslots == 0 {
+		return nil, ErrZeroSlots
+	}
+	if len(nodes) == 0 {
+		return nil, ErrZeroNodes
+	}
+	if cacheSize <= 0 {
+		return nil, ErrInvalidCacheSize
+	}
+
+	return &AHRW{
+		nodes:     uniqNodes(nodes),
+		nslots:    nslots,
+		cache:     newSlotCache(cacheSize),
+		kind:      kindCache,
+		cacheSize: cacheSize,
+	}, nil
+}
+
+// NewBounded returns an instance of AHRW with nslots slots distributed to
+// nodes as with New, except that no node is allowed to receive more than
+// its fair share of slots scaled by the load factor c (c must be >= 1.0):
+// ceil(c * nslots * w / W), where w is the node's weight (from
+// [WeightedNode.Weight] if every node implements it, 1 otherwise) and W
+// is the total weight of all nodes. When the top-ranked node for a slot
+// is already at capacity, the slot falls through to the next-ranked
+// node, and so on.
+//
+// Because assignment is order-dependent this way, NewBounded computes
+// the full slot->node mapping eagerly, processing slots in ascending
+// index order, rather than lazily like New does.
+func NewBounded(nslots uint64, nodes []Node, c float64) (*AHRW, error) {
+	if nslots == 0 {
+		return nil, ErrZeroSlots
+	}
+	if len(nodes) == 0 {
+		return nil, ErrZeroNodes
+	}
+	if c < 1.0 {
+		return nil, ErrInvalidLoadFactor
+	}
+
+	uniq := uniqNodes(nodes)
+
+	weights := make([]float64, len(uniq))
+	allWeighted := true
+	totalWeight := 0.0
+	for i, n := range uniq {
+		wn, ok := n.(WeightedNode)
+		if !ok {
+			allWeighted = false
+			break
+		}
+		weights[i] = wn.Weight()
+		totalWeight += weights[i]
+	}
+	if !allWeighted {
+		for i := range weights {
+			weights[i] = 1
+		}
+		totalWeight = float64(len(uniq))
+	}
+
+	capacities := make([]int, len(uniq))
+	for i, w := range weights {
+		capacities[i] = int(math.Ceil(c * float64(nslots) * w / totalWeight))
+	}
+
+	h := &AHRW{
+		nodes:      uniq,
+		nslots:     nslots,
+		m:          make([]atomic.Pointer[Node], nslots),
+		loads:      make([]int, len(uniq)),
+		kind:       kindBounded,
+		loadFactor: c,
+	}
+	if allWeighted {
+		h.weights = weights
+	}
+
+	remaining := make([]int, len(uniq))
+	copy(remaining, capacities)
+
+	buf := make([]byte, 8)
+	hash := xxh3.New()
+	scores := make([]scoredNode, len(uniq))
+	for slot := uint64(0); slot < nslots; slot++ {
+		binary.BigEndian.PutUint64(buf, slot)
+		for i := range uniq {
+			scores[i] = h.scoreNode(hash, buf, i)
+		}
+		slices.SortFunc(scores, h.scoredCompare)
+		for _, sn := range scores {
+			if remaining[sn.idx] <= 0 {
+				continue
+			}
+			remaining[sn.idx]--
+			h.loads[sn.idx]++
+			h.m[slot].Store(&h.nodes[sn.idx])
+			break
+		}
+	}
+
+	return h, nil
+}
+
+// LoadDistribution returns the number of slots currently assigned to
+// each node, keyed by its NodeID, for an AHRW instance created with
+// NewBounded. It returns nil for instances created any other way.
+func (h *AHRW) LoadDistribution() map[string]int {
+	if h.loads == nil {
+		return nil
+	}
+	dist := make(map[string]int, len(h.nodes))
+	for i, n := range h.nodes {
+		dist[string(n.NodeID())] = h.loads[i]
+	}
+	return dist
+}
+
+// maxUint64AsFloat is 2**64, used to scale a 64-bit hash into (0,1).
+const maxUint64AsFloat = 1 << 64
+
+// scoreFor returns the logarithmic weighted rendezvous hashing score
+// for a node's hash for the current slot and the node's weight. Higher
+// wins. Only used for weighted instances: unweighted instances compare
+// raw hashes directly instead, since routing them through this formula
+// would narrow full 64-bit hash comparisons down to float64's 52-bit
+// mantissa, biasing ties toward the lower node index and changing slot
+// assignment versus comparing hashes directly.
+func scoreFor(hash uint64, weight float64) float64 {
+	p := float64(hash) / maxUint64AsFloat
+	if p >= 1 {
+		// float64(hash) rounds up to maxUint64AsFloat for the ~1024
+		// largest hash values, which would otherwise make p == 1,
+		// math.Log(p) == 0 and the score -Inf: the strongest
+		// candidate ranked as the weakest. Clamp to the largest
+		// float64 strictly below 1 so those hashes still score the
+		// highest, as intended.
+		p = math.Nextafter(1, 0)
+	}
+	return -weight / math.Log(p)
+}
+
+// scoredNode carries both the raw hash and, for weighted instances, the
+// score derived from it for one node at one slot, so callers can rank
+// by whichever is appropriate without re-hashing.
+type scoredNode struct {
+	hash  uint64
+	score float64
+	idx   int
+}
+
+// scoreNode hashes node i against buf (the slot being computed) and
+// returns its scoredNode, using the logarithmic weighted score if h is
+// weighted or the raw hash otherwise.
+func (h *AHRW) scoreNode(hash *xxh3.Hasher, buf []byte, i int) scoredNode {
+	hash.Reset()
+	hash.Write(buf)
+	hash.Write(h.nodes[i].NodeID())
+	hv := hash.Sum64()
+	sn := scoredNode{hash: hv, idx: i}
+	if h.weights != nil {
+		sn.score = scoreFor(hv, h.weights[i])
+	}
+	return sn
+}
+
+// scoredLess reports whether a ranks strictly below b: by raw hash for
+// unweighted instances, or by the logarithmic weighted score otherwise.
+func (h *AHRW) scoredLess(a, b scoredNode) bool {
+	if h.weights == nil {
+		return a.hash < b.hash
+	}
+	return a.score < b.score
+}
+
+// scoredCompare orders scoredNodes from highest to lowest rank, for use
+// with slices.SortFunc.
+func (h *AHRW) scoredCompare(a, b scoredNode) int {
+	if h.scoredLess(a, b) {
+		return 1
+	}
+	if h.scoredLess(b, a) {
+		return -1
+	}
+	return 0
+}
+
 func (h *AHRW) calculateNode(slot uint64) *Node {
 	buf := make([]byte, 8)
 	binary.BigEndian.PutUint64(buf, slot)
 
 	hash := xxh3.New()
 
-	hrw := uint64(0)
+	var best scoredNode
 	hrwidx := 0
 	for i := 0; i < len(h.nodes); i++ {
-		hash.Reset()
-		hash.Write(buf)
-		hash.Write(h.nodes[i].NodeID())
-		weight := hash.Sum64()
-		if weight > hrw {
-			hrw = weight
+		sn := h.scoreNode(hash, buf, i)
+		if i == 0 || h.scoredLess(best, sn) {
+			best = sn
 			hrwidx = i
 		}
 	}
@@ -143,6 +574,14 @@ func (h *AHRW) calculateNode(slot uint64) *Node {
 }
 
 func (h *AHRW) lookupSlot(slot uint64) Node {
+	if h.cache != nil {
+		node, ok := h.cache.get(slot)
+		if !ok {
+			node = h.calculateNode(slot)
+			h.cache.add(slot, node)
+		}
+		return *node
+	}
 	node := h.m[slot].Load()
 	if node == nil {
 		node = h.calculateNode(slot)
@@ -151,19 +590,147 @@ func (h *AHRW) lookupSlot(slot uint64) Node {
 	return *node
 }
 
+// calculateTopK computes, for given slot, the k nodes with highest HRW
+// score, ordered from highest to lowest. A running sorted window of
+// size k is maintained instead of sorting the full node list, which pays
+// off as long as k is small relative to len(h.nodes).
+func (h *AHRW) calculateTopK(slot uint64, k int) []Node {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, slot)
+
+	hash := xxh3.New()
+
+	top := make([]scoredNode, 0, k)
+	for i := 0; i < len(h.nodes); i++ {
+		sn := h.scoreNode(hash, buf, i)
+
+		pos := len(top)
+		for pos > 0 && h.scoredLess(top[pos-1], sn) {
+			pos--
+		}
+		if pos >= k {
+			continue
+		}
+		if len(top) < k {
+			top = append(top, scoredNode{})
+		}
+		copy(top[pos+1:], top[pos:len(top)-1])
+		top[pos] = sn
+	}
+
+	nodes := make([]Node, len(top))
+	for i, sn := range top {
+		nodes[i] = h.nodes[sn.idx]
+	}
+	return nodes
+}
+
+func (h *AHRW) lookupTopK(slot uint64, k int) []Node {
+	if k > len(h.nodes) {
+		k = len(h.nodes)
+	}
+	if h.topk != nil && k <= h.k {
+		cached := h.topk[slot].Load()
+		if cached == nil {
+			full := h.calculateTopK(slot, h.k)
+			cached = &full
+			h.topk[slot].Store(cached)
+		}
+		full := *cached
+		nodes := make([]Node, k)
+		copy(nodes, full[:k])
+		return nodes
+	}
+	return h.calculateTopK(slot, k)
+}
+
+// calculateRank computes the full permutation of node indices for given
+// slot, ordered from highest to lowest HRW score.
+func (h *AHRW) calculateRank(slot uint64) []uint32 {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, slot)
+
+	hash := xxh3.New()
+
+	scores := make([]scoredNode, len(h.nodes))
+	for i := range h.nodes {
+		scores[i] = h.scoreNode(hash, buf, i)
+	}
+	slices.SortFunc(scores, h.scoredCompare)
+
+	perm := make([]uint32, len(scores))
+	for i, sn := range scores {
+		perm[i] = uint32(sn.idx)
+	}
+	return perm
+}
+
+// ensureRank lazily allocates the per-slot ranking cache. It is only
+// grown once, on first use of RankNodesForSlot/RankNodesForBytes, so
+// AHRW instances that never rank nodes don't pay for it. It's a no-op
+// for NewWithCache instances: a dense, nslots-sized ranking table would
+// defeat the whole point of bounding their memory to cacheSize, so
+// those instead recompute the ranking on every call (see lookupRank).
+func (h *AHRW) ensureRank() {
+	if h.kind == kindCache {
+		return
+	}
+	h.rankOnce.Do(func() {
+		h.rank = make([]atomic.Pointer[[]uint32], h.nslots)
+	})
+}
+
+func (h *AHRW) lookupRank(slot uint64) []Node {
+	if h.rank == nil {
+		return h.nodesForRank(h.calculateRank(slot))
+	}
+	perm := h.rank[slot].Load()
+	if perm == nil {
+		computed := h.calculateRank(slot)
+		perm = &computed
+		h.rank[slot].Store(perm)
+	}
+	return h.nodesForRank(*perm)
+}
+
+func (h *AHRW) nodesForRank(perm []uint32) []Node {
+	nodes := make([]Node, len(perm))
+	for i, idx := range perm {
+		nodes[i] = h.nodes[idx]
+	}
+	return nodes
+}
+
 // NSlots returns number of slots in this AHRW instance.
 func (h *AHRW) NSlots() uint64 {
-	return uint64(len(h.m))
+	return h.nslots
 }
 
 // NodeForSlot returns mapped node for specified slot.
 // Useful if you'd like to implement hashing of your objects
 // on your own.
 func (h *AHRW) NodeForSlot(slot uint64) (Node, error) {
-	if slot >= uint64(len(h.m)) {
+	if slot >= h.nslots {
+		return nil, ErrSlotOutOfRange
+	}
+	if h.topk == nil {
+		return h.lookupSlot(slot), nil
+	}
+	return h.lookupTopK(slot, 1)[0], nil
+}
+
+// NodesForSlot returns up to k nodes mapped to specified slot, ordered
+// from highest to lowest HRW weight. k is clamped to the number of
+// nodes in this AHRW instance. Useful if you'd like to implement hashing
+// of your objects on your own, or to pick k replicas for a given slot.
+func (h *AHRW) NodesForSlot(slot uint64, k int) ([]Node, error) {
+	if slot >= h.nslots {
 		return nil, ErrSlotOutOfRange
 	}
-	return h.lookupSlot(slot), nil
+	if k <= 0 {
+		return nil, ErrInvalidK
+	}
+	return h.lookupTopK(slot, k), nil
 }
 
 // NodeForString maps string identifying some object to one
@@ -175,7 +742,308 @@ func (h *AHRW) NodeForString(s string) Node {
 // NodeForBytes maps slice of bytes identifying some object to one
 // of nodes provided to this AHRW instance.
 func (h *AHRW) NodeForBytes(s []byte) Node {
-	return h.lookupSlot(SlotForBytes(uint64(len(h.m)), s))
+	if h.topk == nil {
+		return h.lookupSlot(SlotForBytes(h.nslots, s))
+	}
+	return h.lookupTopK(SlotForBytes(h.nslots, s), 1)[0]
+}
+
+// NodesForString maps string identifying some object to up to k nodes
+// provided to this AHRW instance, ordered from highest to lowest HRW
+// weight. k is clamped to the number of nodes in this AHRW instance. If
+// k <= 0, NodesForString returns nil.
+func (h *AHRW) NodesForString(s string, k int) []Node {
+	return h.NodesForBytes([]byte(s), k)
+}
+
+// NodesForBytes maps slice of bytes identifying some object to up to k
+// nodes provided to this AHRW instance, ordered from highest to lowest
+// HRW weight. k is clamped to the number of nodes in this AHRW instance.
+// If k <= 0, NodesForBytes returns nil.
+func (h *AHRW) NodesForBytes(s []byte, k int) []Node {
+	if k <= 0 {
+		return nil
+	}
+	return h.lookupTopK(SlotForBytes(h.nslots, s), k)
+}
+
+// RankNodesForSlot returns every node provided to this AHRW instance,
+// ordered from highest to lowest HRW score for the given slot. It's
+// useful for failover chains (try RankNodesForSlot(slot)[0], fall back
+// to [1] on failure, and so on) or for quorum reads where the required
+// replica count isn't known up front.
+//
+// The permutation of node indices for each slot is cached lazily behind
+// an atomic pointer, the same way single-node lookup is, so repeated
+// calls remain amortized O(1). The one exception is NewWithCache
+// instances, which recompute the ranking on every call instead of
+// caching it: caching it would mean allocating an nslots-sized table,
+// defeating the bounded memory NewWithCache exists to provide.
+func (h *AHRW) RankNodesForSlot(slot uint64) ([]Node, error) {
+	if slot >= h.nslots {
+		return nil, ErrSlotOutOfRange
+	}
+	h.ensureRank()
+	return h.lookupRank(slot), nil
+}
+
+// RankNodesForBytes returns every node provided to this AHRW instance,
+// ordered from highest to lowest HRW score for the slot identifying the
+// given object. See [AHRW.RankNodesForSlot] for details.
+func (h *AHRW) RankNodesForBytes(s []byte) []Node {
+	h.ensureRank()
+	return h.lookupRank(SlotForBytes(h.nslots, s))
+}
+
+// weightOf returns n's weight if it implements [WeightedNode], 1
+// otherwise.
+func weightOf(n Node) float64 {
+	if wn, ok := n.(WeightedNode); ok {
+		return wn.Weight()
+	}
+	return 1
+}
+
+// weightedAdapter lets a plain Node stand in as a [WeightedNode] with a
+// default weight, for rebuilding a weighted AHRW from nodes that don't
+// all implement WeightedNode themselves.
+type weightedAdapter struct {
+	Node
+	w float64
+}
+
+func (a weightedAdapter) Weight() float64 {
+	return a.w
+}
+
+// insertNode returns nodes with n inserted at its sorted-by-NodeID
+// position, replacing any existing node sharing n's NodeID, along with
+// the index n ends up at and whether it replaced an existing node.
+func insertNode(nodes []Node, n Node) ([]Node, int, bool) {
+	idx, found := slices.BinarySearchFunc(nodes, n.NodeID(), func(a Node, id []byte) int {
+		return bytes.Compare(a.NodeID(), id)
+	})
+	result := make([]Node, 0, len(nodes)+1)
+	result = append(result, nodes[:idx]...)
+	result = append(result, n)
+	if found {
+		result = append(result, nodes[idx+1:]...)
+	} else {
+		result = append(result, nodes[idx:]...)
+	}
+	return result, idx, found
+}
+
+// insertWeight mirrors insertNode for the parallel weights slice.
+func insertWeight(weights []float64, idx int, found bool, w float64) []float64 {
+	result := make([]float64, 0, len(weights)+1)
+	result = append(result, weights[:idx]...)
+	result = append(result, w)
+	if found {
+		result = append(result, weights[idx+1:]...)
+	} else {
+		result = append(result, weights[idx:]...)
+	}
+	return result
+}
+
+// rebuild reconstructs an AHRW over newNodes using whichever constructor
+// originally created h, for AHRW variants that [AHRW.WithNode] and
+// [AHRW.WithoutNode] don't know how to update incrementally. newNodes
+// must be non-empty; rebuild returns h unchanged otherwise, since no
+// constructor accepts zero nodes; WithNode always passes at least one
+// node and WithoutNode checks this before calling rebuild anyway. It also falls back to h, rather than
+// returning a nil *AHRW, if the constructor it delegates to errors for
+// any other reason.
+func (h *AHRW) rebuild(newNodes []Node) *AHRW {
+	if len(newNodes) == 0 {
+		return h
+	}
+	switch h.kind {
+	case kindTopK:
+		if child, err := NewTopK(h.nslots, h.k, newNodes); err == nil {
+			return child
+		}
+		return h
+	case kindBounded:
+		if child, err := NewBounded(h.nslots, newNodes, h.loadFactor); err == nil {
+			return child
+		}
+		return h
+	case kindCache:
+		if child, err := NewWithCache(h.nslots, h.cacheSize, newNodes); err == nil {
+			return child
+		}
+		return h
+	default:
+		if h.weights == nil {
+			if child, err := New(h.nslots, newNodes); err == nil {
+				return child
+			}
+			return h
+		}
+		weighted := make([]WeightedNode, len(newNodes))
+		for i, n := range newNodes {
+			wn, ok := n.(WeightedNode)
+			if !ok {
+				wn = weightedAdapter{n, 1}
+			}
+			weighted[i] = wn
+		}
+		if child, err := NewWeighted(h.nslots, weighted); err == nil {
+			return child
+		}
+		return h
+	}
+}
+
+// WithNode returns a new AHRW with n added to the set of nodes (or
+// replacing the existing node sharing n's NodeID, if any).
+//
+// For AHRW instances created by New or NewWeighted, already-computed
+// slot mappings are carried over directly instead of being recomputed:
+// adding n can only change a slot's winner if n's score beats the
+// existing winner's, which is checked with two extra hashes per
+// already-computed slot (one to re-derive the existing winner's score,
+// one for n's) rather than rescoring every node. This turns what would
+// be an O(nslots*nNodes) rebuild into O(nslots). Slots h hadn't computed
+// yet are left for lazy computation on next access, same as h itself.
+//
+// Other AHRW variants (from NewTopK, NewBounded, NewWithCache) don't
+// support this optimization and are rebuilt from scratch with the same
+// constructor used to create h.
+func (h *AHRW) WithNode(n Node) *AHRW {
+	if h.kind != kindPlain {
+		newNodes, _, _ := insertNode(h.nodes, n)
+		return h.rebuild(newNodes)
+	}
+
+	weighted := h.weights != nil
+	newNodes, idx, found := insertNode(h.nodes, n)
+	var newWeights []float64
+	var newWeight float64 = 1
+	if weighted {
+		newWeight = weightOf(n)
+		newWeights = insertWeight(h.weights, idx, found, newWeight)
+	}
+
+	child := &AHRW{
+		nodes:   newNodes,
+		nslots:  h.nslots,
+		m:       make([]atomic.Pointer[Node], h.nslots),
+		weights: newWeights,
+		kind:    h.kind,
+	}
+
+	buf := make([]byte, 8)
+	hash := xxh3.New()
+	for slot := uint64(0); slot < h.nslots; slot++ {
+		winner := h.m[slot].Load()
+		if winner == nil {
+			continue
+		}
+		binary.BigEndian.PutUint64(buf, slot)
+
+		if found && bytes.Equal((*winner).NodeID(), n.NodeID()) {
+			// This slot's winner is the very node being replaced.
+			// Unweighted scoring only depends on NodeID, which n
+			// shares with it, so n keeps winning unconditionally.
+			// Weighted scoring depends on the (possibly changed)
+			// weight too, which can also flip the outcome against
+			// other nodes, so leave it for a full recompute.
+			if !weighted {
+				child.m[slot].Store(&newNodes[idx])
+			}
+			continue
+		}
+
+		hash.Reset()
+		hash.Write(buf)
+		hash.Write((*winner).NodeID())
+		oldHash := hash.Sum64()
+
+		hash.Reset()
+		hash.Write(buf)
+		hash.Write(n.NodeID())
+		newHash := hash.Sum64()
+
+		if weighted {
+			oldIdx, _ := slices.BinarySearchFunc(newNodes, (*winner).NodeID(), func(a Node, id []byte) int {
+				return bytes.Compare(a.NodeID(), id)
+			})
+			if scoreFor(newHash, newWeight) > scoreFor(oldHash, newWeights[oldIdx]) {
+				continue
+			}
+		} else if newHash > oldHash {
+			continue
+		}
+		child.m[slot].Store(winner)
+	}
+
+	return child
+}
+
+// WithoutNode returns a new AHRW with the node identified by id removed
+// from the set of nodes. If no node has this id, the returned AHRW is
+// otherwise equivalent to h. Removing the last remaining node is
+// refused and h is returned unchanged instead, since every constructor
+// rejects an empty node set with [ErrZeroNodes] and WithoutNode must
+// not produce an instance that panics on lookup.
+//
+// For AHRW instances created by New or NewWeighted, removing a node
+// can only affect the slots it already won, so every other slot's
+// already-computed mapping is carried over unchanged; only the affected
+// slots are left for lazy recomputation over the reduced node set.
+//
+// Other AHRW variants (from NewTopK, NewBounded, NewWithCache) don't
+// support this optimization and are rebuilt from scratch with the same
+// constructor used to create h.
+func (h *AHRW) WithoutNode(id []byte) *AHRW {
+	idx, found := slices.BinarySearchFunc(h.nodes, id, func(a Node, id []byte) int {
+		return bytes.Compare(a.NodeID(), id)
+	})
+
+	newNodes := h.nodes
+	newWeights := h.weights
+	if found {
+		newNodes = make([]Node, 0, len(h.nodes)-1)
+		newNodes = append(newNodes, h.nodes[:idx]...)
+		newNodes = append(newNodes, h.nodes[idx+1:]...)
+		if h.weights != nil {
+			newWeights = make([]float64, 0, len(h.weights)-1)
+			newWeights = append(newWeights, h.weights[:idx]...)
+			newWeights = append(newWeights, h.weights[idx+1:]...)
+		}
+	}
+
+	if len(newNodes) == 0 {
+		return h
+	}
+
+	if h.kind != kindPlain {
+		return h.rebuild(newNodes)
+	}
+
+	child := &AHRW{
+		nodes:   newNodes,
+		nslots:  h.nslots,
+		m:       make([]atomic.Pointer[Node], h.nslots),
+		weights: newWeights,
+		kind:    h.kind,
+	}
+
+	for slot := uint64(0); slot < h.nslots; slot++ {
+		winner := h.m[slot].Load()
+		if winner == nil {
+			continue
+		}
+		if bytes.Equal((*winner).NodeID(), id) {
+			continue
+		}
+		child.m[slot].Store(winner)
+	}
+
+	return child
 }
 
 // SlotForBytes uniformly maps byte slice identifying some object
@@ -24,3 +24,120 @@ func Example() {
 		fmt.Printf("%s -> %s\n", obj, h.NodeForString(obj).(*ahrw.Server).Handle().(string))
 	}
 }
+
+// weightedServer adds a capacity share on top of ahrw.Server.
+type weightedServer struct {
+	*ahrw.Server
+	weight float64
+}
+
+func (s *weightedServer) Weight() float64 {
+	return s.weight
+}
+
+func ExampleAHRW_weighted() {
+	nodes := []ahrw.WeightedNode{
+		&weightedServer{ahrw.NewServer("server1", "red"), 1},
+		&weightedServer{ahrw.NewServer("server2", "orange"), 2},
+		&weightedServer{ahrw.NewServer("server3", "yellow"), 4},
+	}
+	h, err := ahrw.NewWeighted(16384, nodes)
+	if err != nil {
+		panic(err)
+	}
+	for _, obj := range []string{"object1", "object2", "object3", "object4", "object5"} {
+		fmt.Printf("%s -> %s\n", obj, h.NodeForString(obj).(*weightedServer).Handle().(string))
+	}
+}
+
+func ExampleAHRW_LoadDistribution() {
+	nodes := []ahrw.Node{
+		ahrw.NewServer("server1", "red"),
+		ahrw.NewServer("server2", "orange"),
+		ahrw.NewServer("server3", "yellow"),
+	}
+	h, err := ahrw.NewBounded(999, nodes, 1.1)
+	if err != nil {
+		panic(err)
+	}
+	total := 0
+	for _, load := range h.LoadDistribution() {
+		total += load
+	}
+	fmt.Printf("total slots assigned: %d\n", total)
+	// Output: total slots assigned: 999
+}
+
+func ExampleAHRW_RankNodesForBytes() {
+	nodes := []ahrw.Node{
+		ahrw.NewServer("server1", "red"),
+		ahrw.NewServer("server2", "orange"),
+		ahrw.NewServer("server3", "yellow"),
+	}
+	h, err := ahrw.New(16384, nodes)
+	if err != nil {
+		panic(err)
+	}
+	ranked := h.RankNodesForBytes([]byte("object1"))
+	for _, n := range ranked {
+		fmt.Println(n.(*ahrw.Server).Handle().(string))
+	}
+}
+
+func ExampleAHRW_withCache() {
+	nodes := []ahrw.Node{
+		ahrw.NewServer("server1", "red"),
+		ahrw.NewServer("server2", "orange"),
+		ahrw.NewServer("server3", "yellow"),
+	}
+	// A million slots for fine-grained rebalancing, but memory bounded
+	// to 1024 cached slot->node mappings rather than a million.
+	h, err := ahrw.NewWithCache(1_000_000, 1024, nodes)
+	if err != nil {
+		panic(err)
+	}
+	for _, obj := range []string{"object1", "object2", "object3"} {
+		fmt.Printf("%s -> %s\n", obj, h.NodeForString(obj).(*ahrw.Server).Handle().(string))
+	}
+}
+
+func ExampleAHRW_WithNode() {
+	nodes := []ahrw.Node{
+		ahrw.NewServer("server1", "red"),
+		ahrw.NewServer("server2", "orange"),
+		ahrw.NewServer("server3", "yellow"),
+	}
+	h, err := ahrw.New(16384, nodes)
+	if err != nil {
+		panic(err)
+	}
+	h = h.WithNode(ahrw.NewServer("server4", "green"))
+	h = h.WithoutNode([]byte("server2"))
+	for _, obj := range []string{"object1", "object2", "object3"} {
+		fmt.Printf("%s -> %s\n", obj, h.NodeForString(obj).(*ahrw.Server).Handle().(string))
+	}
+}
+
+func ExampleAHRW_NodesForString() {
+	nodes := []ahrw.Node{
+		ahrw.NewServer("server1", "red"),
+		ahrw.NewServer("server2", "orange"),
+		ahrw.NewServer("server3", "yellow"),
+		ahrw.NewServer("server4", "green"),
+		ahrw.NewServer("server5", "blue"),
+		ahrw.NewServer("server6", "indigo"),
+		ahrw.NewServer("server7", "violet"),
+	}
+	h, err := ahrw.NewTopK(16384, 3, nodes)
+	if err != nil {
+		panic(err)
+	}
+	for _, obj := range []string{"object1", "object2"} {
+		replicas := h.NodesForString(obj, 3)
+		colors := make([]string, len(replicas))
+		for i, n := range replicas {
+			colors[i] = n.(*ahrw.Server).Handle().(string)
+		}
+		fmt.Printf("%s -> %v\n", obj, colors)
+	}
+}